@@ -0,0 +1,143 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/btcsuite/btcutil"
+)
+
+// maxAddrsPerSubscribe caps how many addresses go into a single "subscribe"
+// emit so the frame stays well under the socket.io/engine.io payload limit.
+const maxAddrsPerSubscribe = 200
+
+// AddressHistoryOptions mirrors the server-side filters Blockbook's
+// getAddressHistory accepts, letting callers narrow a history fetch instead
+// of always paging the full set of transactions for an address.
+type AddressHistoryOptions struct {
+	From             int
+	To               int
+	Start            int32
+	End              int32
+	QueryMempoolOnly bool
+	// Details selects how much data comes back per item: "basic", "txids",
+	// or "txs" (the default used by GetTransactions).
+	Details string
+}
+
+// ListenAddresses subscribes to tx notifications for every address in addrs
+// in as few "subscribe" emits as possible, batching each emit to
+// maxAddrsPerSubscribe addresses to stay under frame size limits.
+func (i *InsightClient) ListenAddresses(addrs []btcutil.Address) {
+	i.subscribedAddrsMtx.Lock()
+	for _, addr := range addrs {
+		i.subscribedAddrs[addr.String()] = true
+	}
+	i.subscribedAddrsMtx.Unlock()
+
+	for from := 0; from < len(addrs); from += maxAddrsPerSubscribe {
+		to := from + maxAddrsPerSubscribe
+		if to > len(addrs) {
+			to = len(addrs)
+		}
+		chunk := make([]string, 0, to-from)
+		for _, addr := range addrs[from:to] {
+			chunk = append(chunk, addr.String())
+		}
+		args := []interface{}{"bitcoind/addresstxid", chunk}
+		i.getSocketClient().Emit("subscribe", args)
+	}
+}
+
+// GetAddressHistory fetches transaction history for addrs with server-side
+// filtering, equivalent to Blockbook's getAddressHistory. Insight has no
+// native equivalent of the block-height or mempool-only filters, so they are
+// passed through as fields of the JSON request body understood by a
+// Blockbook-compatible addrs/txs endpoint; an Insight server that ignores
+// them simply returns its usual unfiltered page.
+func (i *InsightClient) GetAddressHistory(addrs []btcutil.Address, opts AddressHistoryOptions) (*TransactionList, error) {
+	type req struct {
+		Addrs            string `json:"addrs"`
+		From             int    `json:"from"`
+		To               int    `json:"to"`
+		Start            int32  `json:"start,omitempty"`
+		End              int32  `json:"end,omitempty"`
+		QueryMempoolOnly bool   `json:"queryMempoolOnly,omitempty"`
+		Details          string `json:"details,omitempty"`
+	}
+	s := ``
+	for n, addr := range addrs {
+		s += addr.String()
+		if n < len(addrs)-1 {
+			s += ","
+		}
+	}
+	r := &req{
+		Addrs:            s,
+		From:             opts.From,
+		To:               opts.To,
+		Start:            opts.Start,
+		End:              opts.End,
+		QueryMempoolOnly: opts.QueryMempoolOnly,
+		Details:          opts.Details,
+	}
+	b, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := i.doRequest("addrs/txs", http.MethodPost, b, nil)
+	if err != nil {
+		return nil, err
+	}
+	tl := new(TransactionList)
+	decoder := json.NewDecoder(resp.Body)
+	defer resp.Body.Close()
+	if err = decoder.Decode(tl); err != nil {
+		return nil, fmt.Errorf("error decoding transaction history: %s\n", err)
+	}
+	for z, tx := range tl.Items {
+		for n, in := range tx.Inputs {
+			f, err := toFloat(in.ValueIface)
+			if err != nil {
+				return nil, err
+			}
+			tl.Items[z].Inputs[n].Value = f
+		}
+		for n, out := range tx.Outputs {
+			f, err := toFloat(out.ValueIface)
+			if err != nil {
+				return nil, err
+			}
+			tl.Items[z].Outputs[n].Value = f
+		}
+	}
+	i.populateRatesAtConfirmation(tl.Items)
+	return tl, nil
+}
+
+// GetMempoolTransactions returns only unconfirmed transactions touching
+// addrs, so wallet startup can reconcile the mempool without paging the
+// full confirmed history.
+func (i *InsightClient) GetMempoolTransactions(addrs []btcutil.Address) ([]Transaction, error) {
+	var txs []Transaction
+	from := 0
+	for {
+		to := from + 50
+		tl, err := i.GetAddressHistory(addrs, AddressHistoryOptions{
+			From:             from,
+			To:               to,
+			QueryMempoolOnly: true,
+			Details:          "txs",
+		})
+		if err != nil {
+			return txs, err
+		}
+		txs = append(txs, tl.Items...)
+		if len(txs) >= tl.TotalItems {
+			break
+		}
+		from = to
+	}
+	return txs, nil
+}
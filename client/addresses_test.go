@@ -0,0 +1,109 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcutil"
+)
+
+func newTestAddr(t *testing.T, encoded string) btcutil.Address {
+	addr, err := btcutil.DecodeAddress(encoded, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("invalid test address %q: %s", encoded, err)
+	}
+	return addr
+}
+
+func newTestInsightClient(t *testing.T, handler http.HandlerFunc) *InsightClient {
+	ts := httptest.NewServer(handler)
+	t.Cleanup(ts.Close)
+	u, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &InsightClient{
+		httpClient: *ts.Client(),
+		apiUrl:     *u,
+	}
+}
+
+func TestGetAddressHistoryPagination(t *testing.T) {
+	allItems := []Transaction{{Txid: "a"}, {Txid: "b"}, {Txid: "c"}}
+	var requests int
+	ic := newTestInsightClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		var body struct {
+			From int `json:"from"`
+			To   int `json:"to"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		to := body.To
+		if to > len(allItems) {
+			to = len(allItems)
+		}
+		page := allItems[body.From:to]
+		json.NewEncoder(w).Encode(&TransactionList{
+			TotalItems: len(allItems),
+			From:       body.From,
+			To:         to,
+			Items:      page,
+		})
+	})
+
+	addrs := []btcutil.Address{newTestAddr(t, "1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN2")}
+	var got []Transaction
+	from := 0
+	for {
+		tl, err := ic.GetAddressHistory(addrs, AddressHistoryOptions{From: from, To: from + 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, tl.Items...)
+		if len(got) >= tl.TotalItems {
+			break
+		}
+		from++
+	}
+	if len(got) != len(allItems) {
+		t.Fatalf("expected %d items, got %d", len(allItems), len(got))
+	}
+	if requests != len(allItems) {
+		t.Fatalf("expected pagination to stop after %d requests, made %d", len(allItems), requests)
+	}
+}
+
+func TestGetMempoolTransactionsSkipsConfirmed(t *testing.T) {
+	mempoolTxs := []Transaction{{Txid: "unconfirmed1"}, {Txid: "unconfirmed2"}}
+	ic := newTestInsightClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			QueryMempoolOnly bool `json:"queryMempoolOnly"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		if !body.QueryMempoolOnly {
+			t.Fatalf("expected queryMempoolOnly to be set on the request")
+		}
+		json.NewEncoder(w).Encode(&TransactionList{
+			TotalItems: len(mempoolTxs),
+			Items:      mempoolTxs,
+		})
+	})
+
+	addrs := []btcutil.Address{newTestAddr(t, "1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN2")}
+	txs, err := ic.GetMempoolTransactions(addrs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txs) != len(mempoolTxs) {
+		t.Fatalf("expected %d mempool txs, got %d", len(mempoolTxs), len(txs))
+	}
+	for _, tx := range txs {
+		if tx.Confirmations != 0 {
+			t.Fatalf("expected only unconfirmed transactions, got one with %d confirmations", tx.Confirmations)
+		}
+	}
+}
@@ -0,0 +1,312 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/btcsuite/btcutil"
+	"github.com/gorilla/websocket"
+	"golang.org/x/net/proxy"
+)
+
+// rpcRequest is the envelope Blockbook expects on its websocket endpoint.
+type rpcRequest struct {
+	Id     string      `json:"id"`
+	Method string      `json:"method"`
+	Params interface{} `json:"params,omitempty"`
+}
+
+// rpcResponse is the envelope Blockbook replies with, either in response to
+// a request (Id set) or as a server-pushed subscription event (Id is the
+// subscription name).
+type rpcResponse struct {
+	Id    string          `json:"id"`
+	Data  json.RawMessage `json:"data"`
+	Error *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string { return e.Message }
+
+// BlockbookClient speaks Blockbook's multiplexed websocket JSON-RPC protocol
+// over a single connection, matching requests to responses by id and
+// dispatching server-pushed subscription events to the notify channels.
+type BlockbookClient struct {
+	apiUrl url.URL
+	conn   *websocket.Conn
+
+	outbound chan rpcRequest
+
+	mtx     sync.Mutex
+	pending map[string]chan rpcResponse
+	nextId  uint64
+
+	blockNotifyChan chan Block
+	txNotifyChan    chan Transaction
+
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+// NewBlockbookClient dials a Blockbook websocket endpoint and starts the
+// read/write pumps. apiUrl should be a ws:// or wss:// URL pointing at
+// Blockbook's /websocket path.
+func NewBlockbookClient(apiUrl string, proxyDialer proxy.Dialer) (*BlockbookClient, error) {
+	u, err := url.Parse(apiUrl)
+	if err != nil {
+		return nil, err
+	}
+	dialer := *websocket.DefaultDialer
+	if proxyDialer != nil {
+		dialer.NetDial = proxyDialer.Dial
+	}
+	conn, _, err := dialer.Dial(u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	bc := &BlockbookClient{
+		apiUrl:          *u,
+		conn:            conn,
+		outbound:        make(chan rpcRequest, 500),
+		pending:         make(map[string]chan rpcResponse),
+		blockNotifyChan: make(chan Block, defaultNotifyBufferSize),
+		txNotifyChan:    make(chan Transaction, defaultNotifyBufferSize),
+		done:            make(chan struct{}),
+	}
+	go bc.writePump()
+	go bc.readPump()
+	if err := bc.subscribeNewBlock(); err != nil {
+		bc.Close()
+		return nil, err
+	}
+	if err := bc.subscribeNewTransaction(); err != nil {
+		bc.Close()
+		return nil, err
+	}
+	return bc, nil
+}
+
+func (bc *BlockbookClient) Close() {
+	bc.closeOnce.Do(func() {
+		close(bc.done)
+		bc.conn.Close()
+	})
+}
+
+func (bc *BlockbookClient) writePump() {
+	for {
+		select {
+		case req := <-bc.outbound:
+			if err := bc.conn.WriteJSON(req); err != nil {
+				log.Errorf("Error writing to blockbook socket: %s", err.Error())
+				return
+			}
+		case <-bc.done:
+			return
+		}
+	}
+}
+
+func (bc *BlockbookClient) readPump() {
+	for {
+		var resp rpcResponse
+		if err := bc.conn.ReadJSON(&resp); err != nil {
+			select {
+			case <-bc.done:
+			default:
+				log.Errorf("Error reading from blockbook socket: %s", err.Error())
+			}
+			return
+		}
+		switch resp.Id {
+		case "subscribeNewBlock":
+			var blk Block
+			if err := json.Unmarshal(resp.Data, &blk); err != nil {
+				log.Errorf("Error decoding newBlock push: %s", err.Error())
+				continue
+			}
+			bc.blockNotifyChan <- blk
+		case "subscribeNewTransaction":
+			var tx Transaction
+			if err := json.Unmarshal(resp.Data, &tx); err != nil {
+				log.Errorf("Error decoding newTransaction push: %s", err.Error())
+				continue
+			}
+			bc.txNotifyChan <- tx
+		case "subscribeAddresses":
+			var notification struct {
+				Address string      `json:"address"`
+				Tx      Transaction `json:"tx"`
+			}
+			if err := json.Unmarshal(resp.Data, &notification); err != nil {
+				log.Errorf("Error decoding address notification push: %s", err.Error())
+				continue
+			}
+			bc.txNotifyChan <- notification.Tx
+		default:
+			bc.mtx.Lock()
+			ch, ok := bc.pending[resp.Id]
+			if ok {
+				delete(bc.pending, resp.Id)
+			}
+			bc.mtx.Unlock()
+			if ok {
+				ch <- resp
+			}
+		}
+	}
+}
+
+// call sends a request and blocks until the matching response arrives.
+func (bc *BlockbookClient) call(method string, params interface{}) (json.RawMessage, error) {
+	id := strconv.FormatUint(atomic.AddUint64(&bc.nextId, 1), 10)
+	ch := make(chan rpcResponse, 1)
+	bc.mtx.Lock()
+	bc.pending[id] = ch
+	bc.mtx.Unlock()
+
+	select {
+	case bc.outbound <- rpcRequest{Id: id, Method: method, Params: params}:
+	case <-bc.done:
+		return nil, errors.New("blockbook client is closed")
+	}
+
+	select {
+	case resp := <-ch:
+		if resp.Error != nil {
+			return nil, resp.Error
+		}
+		return resp.Data, nil
+	case <-time.After(30 * time.Second):
+		bc.mtx.Lock()
+		delete(bc.pending, id)
+		bc.mtx.Unlock()
+		return nil, errors.New("timed out waiting for blockbook response")
+	case <-bc.done:
+		return nil, errors.New("blockbook client is closed")
+	}
+}
+
+func (bc *BlockbookClient) subscribeNewBlock() error {
+	_, err := bc.call("subscribeNewBlock", nil)
+	return err
+}
+
+func (bc *BlockbookClient) subscribeNewTransaction() error {
+	_, err := bc.call("subscribeNewTransaction", nil)
+	return err
+}
+
+func (bc *BlockbookClient) subscribeAddresses(addrs []string) error {
+	type params struct {
+		Addresses []string `json:"addresses"`
+	}
+	_, err := bc.call("subscribeAddresses", &params{Addresses: addrs})
+	return err
+}
+
+func (bc *BlockbookClient) ListenAddress(addr btcutil.Address) {
+	if err := bc.subscribeAddresses([]string{addr.String()}); err != nil {
+		log.Errorf("Error subscribing to address %s: %s", addr.String(), err.Error())
+	}
+}
+
+func (bc *BlockbookClient) BlockNotify() <-chan Block {
+	return bc.blockNotifyChan
+}
+
+func (bc *BlockbookClient) TransactionNotify() <-chan Transaction {
+	return bc.txNotifyChan
+}
+
+func (bc *BlockbookClient) GetTransaction(txid string) (*Transaction, error) {
+	data, err := bc.call("getTransaction", map[string]string{"txid": txid})
+	if err != nil {
+		return nil, err
+	}
+	tx := new(Transaction)
+	if err := json.Unmarshal(data, tx); err != nil {
+		return nil, fmt.Errorf("error decoding transaction: %s\n", err)
+	}
+	return tx, nil
+}
+
+func (bc *BlockbookClient) GetTransactions(addrs []btcutil.Address) ([]Transaction, error) {
+	strs := make([]string, len(addrs))
+	for n, a := range addrs {
+		strs[n] = a.String()
+	}
+	data, err := bc.call("getAccountInfo", map[string]interface{}{
+		"addresses": strs,
+		"details":   "txs",
+	})
+	if err != nil {
+		return nil, err
+	}
+	type accountInfo struct {
+		Transactions []Transaction `json:"transactions"`
+	}
+	info := new(accountInfo)
+	if err := json.Unmarshal(data, info); err != nil {
+		return nil, fmt.Errorf("error decoding transaction list: %s\n", err)
+	}
+	return info.Transactions, nil
+}
+
+func (bc *BlockbookClient) GetUtxos(addrs []btcutil.Address) ([]Utxo, error) {
+	strs := make([]string, len(addrs))
+	for n, a := range addrs {
+		strs[n] = a.String()
+	}
+	data, err := bc.call("getUtxo", map[string]interface{}{"addresses": strs})
+	if err != nil {
+		return nil, err
+	}
+	utxos := []Utxo{}
+	if err := json.Unmarshal(data, &utxos); err != nil {
+		return nil, fmt.Errorf("error decoding utxo list: %s\n", err)
+	}
+	return utxos, nil
+}
+
+func (bc *BlockbookClient) Broadcast(tx []byte) (string, error) {
+	data, err := bc.call("sendTransaction", map[string]string{"hex": fmt.Sprintf("%x", tx)})
+	if err != nil {
+		return "", err
+	}
+	type result struct {
+		Result string `json:"result"`
+	}
+	r := new(result)
+	if err := json.Unmarshal(data, r); err != nil {
+		return "", fmt.Errorf("error decoding broadcast result: %s\n", err)
+	}
+	return r.Result, nil
+}
+
+func (bc *BlockbookClient) GetBestBlock() (*Block, error) {
+	data, err := bc.call("getInfo", nil)
+	if err != nil {
+		return nil, err
+	}
+	type blockbookInfo struct {
+		BestHeight int32  `json:"bestHeight"`
+		BestHash   string `json:"bestHash"`
+	}
+	info := new(blockbookInfo)
+	if err := json.Unmarshal(data, info); err != nil {
+		return nil, fmt.Errorf("error decoding info: %s\n", err)
+	}
+	return &Block{Hash: info.BestHash, Height: info.BestHeight}, nil
+}
+
+var _ ChainClient = (*BlockbookClient)(nil)
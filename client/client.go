@@ -19,6 +19,7 @@ import (
 	"net/url"
 	"path"
 	"strconv"
+	"sync"
 	"time"
 )
 
@@ -27,16 +28,33 @@ var log = logging.MustGetLogger("client")
 type InsightClient struct {
 	httpClient      http.Client
 	apiUrl          url.URL
+	proxyDialer     proxy.Dialer
+	retryPolicy     RetryPolicy
 	blockNotifyChan chan Block
 	txNotifyChan    chan Transaction
+
+	socketClientMtx sync.RWMutex
 	socketClient    SocketClient
+
+	subscribedAddrsMtx sync.Mutex
+	subscribedAddrs    map[string]bool
+
+	connStateChan  chan ConnectionState
+	disconnectChan chan struct{}
+	closeChan      chan struct{}
+	closeOnce      sync.Once
+
+	fiatRateProviderMtx sync.Mutex
+	fiatRateProvider    FiatRateProvider
+	fiatCurrency        string
 }
 
-func NewInsightClient(apiUrl string, proxyDialer proxy.Dialer) (*InsightClient, error) {
-	u, err := url.Parse(apiUrl)
-	if err != nil {
-		return nil, err
-	}
+var errNoFiatRateProvider = errors.New("no FiatRateProvider configured; call SetFiatRateProvider first")
+
+// dialInsightSocket opens a single socket.io connection to the given
+// Insight API URL, blocking until it is established or the 10-second
+// connect timeout elapses.
+func dialInsightSocket(u url.URL, proxyDialer proxy.Dialer) (SocketClient, error) {
 	var port int
 	var secure bool
 	if u.Scheme == "https" {
@@ -48,10 +66,6 @@ func NewInsightClient(apiUrl string, proxyDialer proxy.Dialer) (*InsightClient,
 	} else {
 		return nil, errors.New("Unknown url scheme")
 	}
-	dial := net.Dial
-	if proxyDialer != nil {
-		dial = proxyDialer.Dial
-	}
 	socketClient, err := gosocketio.Dial(
 		gosocketio.GetUrl(u.Host, port, secure),
 		transport.GetDefaultWebsocketTransport(proxyDialer),
@@ -64,55 +78,152 @@ func NewInsightClient(apiUrl string, proxyDialer proxy.Dialer) (*InsightClient,
 		close(socketReady)
 	})
 	ticker := time.NewTicker(time.Second * 10)
+	defer ticker.Stop()
 	select {
 	case <-ticker.C:
+		socketClient.Close()
 		return nil, errors.New("Timed out waiting for websocket connection")
 	case <-socketReady:
 		break
 	}
+	return socketClient, nil
+}
 
-	bch := make(chan Block)
-	tch := make(chan Transaction)
-	tbTransport := &http.Transport{Dial: dial}
-	ic := &InsightClient{
-		http.Client{Timeout: time.Second * 30, Transport: tbTransport},
-		*u,
-		bch,
-		tch,
-		socketClient,
+// NewInsightClient dials apiUrl with DefaultClientConfig(). Use
+// NewInsightClientWithConfig to tune TLS, connection pooling, or retries.
+func NewInsightClient(apiUrl string, proxyDialer proxy.Dialer) (*InsightClient, error) {
+	return NewInsightClientWithConfig(apiUrl, proxyDialer, DefaultClientConfig())
+}
+
+// NewInsightClientWithConfig dials apiUrl and wires the given ClientConfig
+// into the underlying HTTP transport: TLS client certs for private
+// Insight/Blockbook deployments, idle connection pooling, and the retry
+// policy doRequest applies to every call.
+func NewInsightClientWithConfig(apiUrl string, proxyDialer proxy.Dialer, config ClientConfig) (*InsightClient, error) {
+	u, err := url.Parse(apiUrl)
+	if err != nil {
+		return nil, err
+	}
+	socketClient, err := dialInsightSocket(*u, proxyDialer)
+	if err != nil {
+		return nil, err
+	}
+
+	dial := net.Dial
+	if proxyDialer != nil {
+		dial = proxyDialer.Dial
+	}
+	tbTransport := &http.Transport{
+		Dial:            dial,
+		TLSClientConfig: config.TLSConfig,
+		MaxIdleConns:    config.MaxIdleConns,
+		IdleConnTimeout: config.IdleConnTimeout,
 	}
+	ic := &InsightClient{
+		httpClient:      http.Client{Timeout: config.RequestTimeout, Transport: tbTransport},
+		apiUrl:          *u,
+		proxyDialer:     proxyDialer,
+		retryPolicy:     config.RetryPolicy,
+		blockNotifyChan: make(chan Block, defaultNotifyBufferSize),
+		txNotifyChan:    make(chan Transaction, defaultNotifyBufferSize),
+		socketClient:    socketClient,
+		subscribedAddrs: make(map[string]bool),
+		connStateChan:   make(chan ConnectionState, 1),
+		disconnectChan:  make(chan struct{}, 1),
+		closeChan:       make(chan struct{}),
+	}
+	ic.watchDisconnect()
 	ic.setupListeners()
+	go ic.runSupervisor()
 	return ic, nil
 }
 
 func (i *InsightClient) Close() {
-	i.socketClient.Close()
+	i.closeOnce.Do(func() {
+		close(i.closeChan)
+		i.getSocketClient().Close()
+	})
+}
+
+// getSocketClient returns the current socket, guarding against the
+// supervisor goroutine swapping it out for a fresh one mid-reconnect.
+func (i *InsightClient) getSocketClient() SocketClient {
+	i.socketClientMtx.RLock()
+	defer i.socketClientMtx.RUnlock()
+	return i.socketClient
+}
+
+// setSocketClient installs a newly (re)dialed socket.
+func (i *InsightClient) setSocketClient(socketClient SocketClient) {
+	i.socketClientMtx.Lock()
+	defer i.socketClientMtx.Unlock()
+	i.socketClient = socketClient
 }
 
-func (i *InsightClient) doRequest(endpoint, method string, body io.Reader, query url.Values) (*http.Response, error) {
+// doRequest issues a request against endpoint, retrying according to
+// i.retryPolicy. 5xx responses and network errors are always retried; other
+// codes are retried only if they're in the policy's RetryOn list (e.g.
+// 429), and a Retry-After response header is honored when present. Any
+// other 4xx is terminal. body is passed as a byte slice rather than an
+// io.Reader so each attempt gets its own fresh reader.
+func (i *InsightClient) doRequest(endpoint, method string, body []byte, query url.Values) (*http.Response, error) {
 	requestUrl := i.apiUrl
 	requestUrl.Path = path.Join(i.apiUrl.Path, endpoint)
-	req, err := http.NewRequest(method, requestUrl.String(), body)
-	if query != nil {
-		req.URL.RawQuery = query.Encode()
-	}
-	if err != nil {
-		return nil, fmt.Errorf("creating request: %s\n", err)
-	}
-	req.Header.Add("Content-Type", "application/json")
 
-	resp, err := i.httpClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	// Try again if for some reason it returned a bad request
-	if resp.StatusCode == http.StatusBadRequest {
-		resp, err = i.httpClient.Do(req)
+	policy := i.retryPolicy
+	if policy.MaxAttempts < 1 {
+		policy.MaxAttempts = 1
 	}
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("status not ok: %s\n", resp.Status)
+
+	var lastErr error
+	for attempt := 0; attempt < policy.MaxAttempts; attempt++ {
+		var bodyReader io.Reader
+		if body != nil {
+			bodyReader = bytes.NewReader(body)
+		}
+		req, err := http.NewRequest(method, requestUrl.String(), bodyReader)
+		if err != nil {
+			return nil, fmt.Errorf("creating request: %s\n", err)
+		}
+		if query != nil {
+			req.URL.RawQuery = query.Encode()
+		}
+		req.Header.Add("Content-Type", "application/json")
+
+		resp, err := i.httpClient.Do(req)
+		if err != nil {
+			lastErr = err
+			time.Sleep(policy.delay(attempt, 0))
+			continue
+		}
+		if resp.StatusCode == http.StatusOK {
+			return resp, nil
+		}
+
+		retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"))
+		lastErr = fmt.Errorf("status not ok: %s\n", resp.Status)
+		resp.Body.Close()
+
+		if !policy.retryable(resp.StatusCode) || attempt == policy.MaxAttempts-1 {
+			return nil, lastErr
+		}
+		time.Sleep(policy.delay(attempt, retryAfter))
 	}
-	return resp, nil
+	return nil, lastErr
+}
+
+// parseRetryAfter parses a Retry-After header's delta-seconds form. It
+// returns 0 (meaning "use the policy's own backoff") for empty or
+// non-numeric values; Insight/Blockbook don't send the HTTP-date form.
+func parseRetryAfter(v string) time.Duration {
+	if v == "" {
+		return 0
+	}
+	secs, err := strconv.Atoi(v)
+	if err != nil || secs < 0 {
+		return 0
+	}
+	return time.Duration(secs) * time.Second
 }
 
 func (i *InsightClient) GetTransaction(txid string) (*Transaction, error) {
@@ -140,7 +251,9 @@ func (i *InsightClient) GetTransaction(txid string) (*Transaction, error) {
 		}
 		tx.Outputs[n].Value = f
 	}
-	return tx, nil
+	txs := []Transaction{*tx}
+	i.populateRatesAtConfirmation(txs)
+	return &txs[0], nil
 }
 
 func (i *InsightClient) GetTransactions(addrs []btcutil.Address) ([]Transaction, error) {
@@ -157,6 +270,7 @@ func (i *InsightClient) GetTransactions(addrs []btcutil.Address) ([]Transaction,
 		}
 		from += 50
 	}
+	i.populateRatesAtConfirmation(txs)
 	return txs, nil
 }
 
@@ -182,7 +296,7 @@ func (i *InsightClient) getTransactions(addrs []btcutil.Address, from, to int) (
 	if err != nil {
 		return nil, err
 	}
-	resp, err := i.doRequest("addrs/txs", http.MethodPost, bytes.NewReader(b), nil)
+	resp, err := i.doRequest("addrs/txs", http.MethodPost, b, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -229,7 +343,7 @@ func (i *InsightClient) GetUtxos(addrs []btcutil.Address) ([]Utxo, error) {
 	if err != nil {
 		return nil, err
 	}
-	resp, err := i.doRequest("addrs/utxo", http.MethodPost, bytes.NewReader(b), nil)
+	resp, err := i.doRequest("addrs/utxo", http.MethodPost, b, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -246,6 +360,7 @@ func (i *InsightClient) GetUtxos(addrs []btcutil.Address) ([]Utxo, error) {
 		}
 		utxos[z].Amount = f
 	}
+	i.populateUtxoRatesAtConfirmation(utxos)
 	return utxos, nil
 }
 
@@ -258,14 +373,18 @@ func (i *InsightClient) TransactionNotify() <-chan Transaction {
 }
 
 func (i *InsightClient) ListenAddress(addr btcutil.Address) {
+	i.subscribedAddrsMtx.Lock()
+	i.subscribedAddrs[addr.String()] = true
+	i.subscribedAddrsMtx.Unlock()
+
 	var args []interface{}
 	args = append(args, "bitcoind/addresstxid")
 	args = append(args, []string{addr.String()})
-	i.socketClient.Emit("subscribe", args)
+	i.getSocketClient().Emit("subscribe", args)
 }
 
 func (i *InsightClient) setupListeners() {
-	i.socketClient.On("bitcoind/hashblock", func(h *gosocketio.Channel, arg interface{}) {
+	i.getSocketClient().On("bitcoind/hashblock", func(h *gosocketio.Channel, arg interface{}) {
 		best, err := i.GetBestBlock()
 		if err != nil {
 			log.Errorf("Error downloading best block: %s", err.Error())
@@ -273,9 +392,9 @@ func (i *InsightClient) setupListeners() {
 		}
 		i.blockNotifyChan <- *best
 	})
-	i.socketClient.Emit("subscribe", protocol.ToArgArray("bitcoind/hashblock"))
+	i.getSocketClient().Emit("subscribe", protocol.ToArgArray("bitcoind/hashblock"))
 
-	i.socketClient.On("bitcoind/addresstxid", func(h *gosocketio.Channel, arg interface{}) {
+	i.getSocketClient().On("bitcoind/addresstxid", func(h *gosocketio.Channel, arg interface{}) {
 		m, ok := arg.(map[string]interface{})
 		if !ok {
 			log.Errorf("Error checking type after socket notification: %T", arg)
@@ -311,7 +430,7 @@ func (i *InsightClient) Broadcast(tx []byte) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	resp, err := i.doRequest("tx/send", http.MethodPost, bytes.NewBuffer(txJson), nil)
+	resp, err := i.doRequest("tx/send", http.MethodPost, txJson, nil)
 	decoder := json.NewDecoder(resp.Body)
 
 	type txid struct {
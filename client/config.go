@@ -0,0 +1,78 @@
+package client
+
+import (
+	"crypto/tls"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy controls how doRequest retries a failed HTTP call. 5xx
+// responses and network errors are always retryable; RetryOn lists
+// additional status codes (e.g. 429) that should also be retried. 4xx
+// codes not in RetryOn are treated as terminal and returned immediately.
+type RetryPolicy struct {
+	MaxAttempts int
+	Backoff     time.Duration
+	RetryOn     []int
+}
+
+func (p RetryPolicy) retryable(status int) bool {
+	if status >= 500 {
+		return true
+	}
+	for _, code := range p.RetryOn {
+		if code == status {
+			return true
+		}
+	}
+	return false
+}
+
+// delay returns the backoff duration for the given attempt (0-indexed),
+// with jitter, honoring retryAfter if the server sent one.
+func (p RetryPolicy) delay(attempt int, retryAfter time.Duration) time.Duration {
+	if retryAfter > 0 {
+		return retryAfter
+	}
+	d := p.Backoff * (1 << uint(attempt))
+	if d > maxBackoff || d <= 0 {
+		d = maxBackoff
+	}
+	jitter := time.Duration(rand.Int63n(int64(d)/2 + 1))
+	return d/2 + jitter
+}
+
+// DefaultRetryPolicy retries server errors, rate limiting, and the
+// occasional spurious 400 a handful of times with a short exponential
+// backoff; every other 4xx is left terminal.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		Backoff:     500 * time.Millisecond,
+		RetryOn:     []int{400, 429},
+	}
+}
+
+// ClientConfig carries the transport-level knobs NewInsightClientWithConfig
+// needs: TLS material for mTLS against private Insight/Blockbook
+// deployments, connection pooling, and the retry policy applied to every
+// doRequest call.
+type ClientConfig struct {
+	TLSConfig       *tls.Config
+	MaxIdleConns    int
+	IdleConnTimeout time.Duration
+	RequestTimeout  time.Duration
+	RetryPolicy     RetryPolicy
+}
+
+// DefaultClientConfig returns the settings NewInsightClient uses: the 30
+// second request timeout it used to hardcode, modest connection pooling, and
+// DefaultRetryPolicy.
+func DefaultClientConfig() ClientConfig {
+	return ClientConfig{
+		MaxIdleConns:    20,
+		IdleConnTimeout: 90 * time.Second,
+		RequestTimeout:  30 * time.Second,
+		RetryPolicy:     DefaultRetryPolicy(),
+	}
+}
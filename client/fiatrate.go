@@ -0,0 +1,292 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// FiatRateProvider is a pluggable source of fiat exchange rates. InsightClient
+// reads every fiat rate from the provider installed with SetFiatRateProvider;
+// it has no socket-based rate source of its own. HTTPFiatRateProvider is the
+// default implementation.
+type FiatRateProvider interface {
+	GetCurrentRates(currencies []string) (map[string]float64, error)
+	GetRatesForTimestamps(timestamps []int64, currency string) (map[int64]float64, error)
+	Subscribe(currency string) <-chan FiatRate
+	Close()
+}
+
+// SetFiatRateProvider installs provider as the source for
+// GetCurrentFiatRates, GetFiatRatesForTimestamps, and SubscribeFiatRates,
+// and currency as the currency GetTransaction/GetTransactions/
+// GetAddressHistory use to populate Transaction.RateAtConfirmation. It must
+// be called before those methods are used; without a provider they return
+// an error, and without a currency RateAtConfirmation is left nil.
+func (i *InsightClient) SetFiatRateProvider(provider FiatRateProvider, currency string) {
+	i.fiatRateProviderMtx.Lock()
+	defer i.fiatRateProviderMtx.Unlock()
+	i.fiatRateProvider = provider
+	i.fiatCurrency = currency
+}
+
+func (i *InsightClient) getFiatRateProvider() (FiatRateProvider, error) {
+	i.fiatRateProviderMtx.Lock()
+	defer i.fiatRateProviderMtx.Unlock()
+	if i.fiatRateProvider == nil {
+		return nil, errNoFiatRateProvider
+	}
+	return i.fiatRateProvider, nil
+}
+
+// confirmationTimestamp returns the unix time a transaction confirmed at,
+// preferring the block's time over the transaction's own (mempool receive)
+// time.
+func confirmationTimestamp(tx Transaction) int64 {
+	if tx.BlockTime != 0 {
+		return tx.BlockTime
+	}
+	return tx.Time
+}
+
+// ratesForTimestamps looks up the configured currency's rate at each of the
+// given unix timestamps in a single provider call, deduplicating repeats.
+// It returns a nil map if no provider/currency has been configured via
+// SetFiatRateProvider.
+func (i *InsightClient) ratesForTimestamps(timestamps []int64) map[int64]float64 {
+	i.fiatRateProviderMtx.Lock()
+	provider := i.fiatRateProvider
+	currency := i.fiatCurrency
+	i.fiatRateProviderMtx.Unlock()
+	if provider == nil || currency == "" {
+		return nil
+	}
+
+	seen := make(map[int64]bool)
+	var unique []int64
+	for _, ts := range timestamps {
+		if ts == 0 || seen[ts] {
+			continue
+		}
+		seen[ts] = true
+		unique = append(unique, ts)
+	}
+	if len(unique) == 0 {
+		return nil
+	}
+
+	rates, err := provider.GetRatesForTimestamps(unique, currency)
+	if err != nil {
+		log.Errorf("Error fetching fiat rates at confirmation: %s", err.Error())
+		return nil
+	}
+	return rates
+}
+
+// populateRatesAtConfirmation fills in RateAtConfirmation on each of txs
+// using the configured FiatRateProvider, keyed by each transaction's
+// confirmation timestamp. It's a no-op if no provider/currency has been
+// configured via SetFiatRateProvider.
+func (i *InsightClient) populateRatesAtConfirmation(txs []Transaction) {
+	timestamps := make([]int64, len(txs))
+	for n, tx := range txs {
+		timestamps[n] = confirmationTimestamp(tx)
+	}
+	rates := i.ratesForTimestamps(timestamps)
+	if rates == nil {
+		return
+	}
+	for n, tx := range txs {
+		rate, ok := rates[confirmationTimestamp(tx)]
+		if !ok {
+			continue
+		}
+		r := rate
+		txs[n].RateAtConfirmation = &r
+	}
+}
+
+// populateUtxoRatesAtConfirmation mirrors populateRatesAtConfirmation for
+// Utxo, keyed by each utxo's confirmation timestamp.
+func (i *InsightClient) populateUtxoRatesAtConfirmation(utxos []Utxo) {
+	timestamps := make([]int64, len(utxos))
+	for n, u := range utxos {
+		timestamps[n] = u.Time
+	}
+	rates := i.ratesForTimestamps(timestamps)
+	if rates == nil {
+		return
+	}
+	for n, u := range utxos {
+		rate, ok := rates[u.Time]
+		if !ok {
+			continue
+		}
+		r := rate
+		utxos[n].RateAtConfirmation = &r
+	}
+}
+
+// GetCurrentFiatRates returns the latest rate for each requested currency.
+func (i *InsightClient) GetCurrentFiatRates(currencies []string) (map[string]float64, error) {
+	provider, err := i.getFiatRateProvider()
+	if err != nil {
+		return nil, err
+	}
+	return provider.GetCurrentRates(currencies)
+}
+
+// GetFiatRatesForTimestamps looks up the historical rate of currency at each
+// of the given unix timestamps, for populating RateAtConfirmation on
+// already-confirmed transactions.
+func (i *InsightClient) GetFiatRatesForTimestamps(timestamps []int64, currency string) (map[int64]float64, error) {
+	provider, err := i.getFiatRateProvider()
+	if err != nil {
+		return nil, err
+	}
+	return provider.GetRatesForTimestamps(timestamps, currency)
+}
+
+// SubscribeFiatRates returns a channel that receives a FiatRate every time
+// the provider refreshes its rate for currency.
+func (i *InsightClient) SubscribeFiatRates(currency string) (<-chan FiatRate, error) {
+	provider, err := i.getFiatRateProvider()
+	if err != nil {
+		return nil, err
+	}
+	return provider.Subscribe(currency), nil
+}
+
+// HTTPFiatRateProvider polls a REST endpoint of the form
+// "<BaseURL>?currency=<code>" returning `{"rate": <float>}` on an interval,
+// and fans the result out to every subscriber of that currency. InsightClient
+// does not install it on its own; pass one to SetFiatRateProvider.
+type HTTPFiatRateProvider struct {
+	BaseURL  string
+	Interval time.Duration
+	client   http.Client
+
+	mtx         sync.Mutex
+	subscribers map[string][]chan FiatRate
+	latest      map[string]float64
+
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+// NewHTTPFiatRateProvider starts a provider polling baseURL every interval.
+func NewHTTPFiatRateProvider(baseURL string, interval time.Duration) *HTTPFiatRateProvider {
+	p := &HTTPFiatRateProvider{
+		BaseURL:     baseURL,
+		Interval:    interval,
+		client:      http.Client{Timeout: 10 * time.Second},
+		subscribers: make(map[string][]chan FiatRate),
+		latest:      make(map[string]float64),
+		done:        make(chan struct{}),
+	}
+	go p.run()
+	return p
+}
+
+func (p *HTTPFiatRateProvider) Close() {
+	p.closeOnce.Do(func() { close(p.done) })
+}
+
+func (p *HTTPFiatRateProvider) run() {
+	ticker := time.NewTicker(p.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-ticker.C:
+			p.poll()
+		}
+	}
+}
+
+func (p *HTTPFiatRateProvider) poll() {
+	p.mtx.Lock()
+	currencies := make([]string, 0, len(p.subscribers))
+	for c := range p.subscribers {
+		currencies = append(currencies, c)
+	}
+	p.mtx.Unlock()
+
+	for _, currency := range currencies {
+		rate, err := p.fetchRate(currency, 0)
+		if err != nil {
+			log.Errorf("Error polling fiat rate for %s: %s", currency, err.Error())
+			continue
+		}
+		sample := FiatRate{Currency: currency, Rate: rate, Timestamp: time.Now().Unix()}
+
+		p.mtx.Lock()
+		p.latest[currency] = rate
+		subs := append([]chan FiatRate(nil), p.subscribers[currency]...)
+		p.mtx.Unlock()
+
+		for _, ch := range subs {
+			select {
+			case ch <- sample:
+			default:
+			}
+		}
+	}
+}
+
+func (p *HTTPFiatRateProvider) fetchRate(currency string, timestamp int64) (float64, error) {
+	url := fmt.Sprintf("%s?currency=%s", p.BaseURL, currency)
+	if timestamp != 0 {
+		url = fmt.Sprintf("%s&timestamp=%d", url, timestamp)
+	}
+	resp, err := p.client.Get(url)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("status not ok: %s", resp.Status)
+	}
+	var body struct {
+		Rate float64 `json:"rate"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return 0, fmt.Errorf("error decoding fiat rate: %s\n", err)
+	}
+	return body.Rate, nil
+}
+
+func (p *HTTPFiatRateProvider) GetCurrentRates(currencies []string) (map[string]float64, error) {
+	rates := make(map[string]float64, len(currencies))
+	for _, currency := range currencies {
+		rate, err := p.fetchRate(currency, 0)
+		if err != nil {
+			return nil, err
+		}
+		rates[currency] = rate
+	}
+	return rates, nil
+}
+
+func (p *HTTPFiatRateProvider) GetRatesForTimestamps(timestamps []int64, currency string) (map[int64]float64, error) {
+	rates := make(map[int64]float64, len(timestamps))
+	for _, ts := range timestamps {
+		rate, err := p.fetchRate(currency, ts)
+		if err != nil {
+			return nil, err
+		}
+		rates[ts] = rate
+	}
+	return rates, nil
+}
+
+func (p *HTTPFiatRateProvider) Subscribe(currency string) <-chan FiatRate {
+	ch := make(chan FiatRate, 1)
+	p.mtx.Lock()
+	p.subscribers[currency] = append(p.subscribers[currency], ch)
+	p.mtx.Unlock()
+	return ch
+}
@@ -0,0 +1,37 @@
+package client
+
+import (
+	"github.com/btcsuite/btcutil"
+)
+
+// ChainClient is the common surface wallet code needs from a chain data
+// backend. InsightClient speaks Bitpay's Insight REST + socket.io API;
+// BlockbookClient speaks Blockbook's websocket JSON-RPC API. Wallet config
+// picks one of the two without the rest of the code needing to know which.
+//
+// This interface only covers the original Insight-equivalent surface;
+// ListenAddresses, GetAddressHistory, GetMempoolTransactions, and the fiat
+// rate methods added to InsightClient since are not part of it yet, so
+// callers that need those still have to depend on *InsightClient directly.
+type ChainClient interface {
+	GetTransaction(txid string) (*Transaction, error)
+	GetTransactions(addrs []btcutil.Address) ([]Transaction, error)
+	GetUtxos(addrs []btcutil.Address) ([]Utxo, error)
+	Broadcast(tx []byte) (string, error)
+	GetBestBlock() (*Block, error)
+	ListenAddress(addr btcutil.Address)
+	BlockNotify() <-chan Block
+	TransactionNotify() <-chan Transaction
+	Close()
+}
+
+// SocketClient is the subset of gosocketio.Client that InsightClient depends
+// on. It exists so tests can swap in a fake socket without dialing a real
+// socket.io server.
+type SocketClient interface {
+	On(name string, f interface{}) error
+	Emit(name string, args []interface{}) error
+	Close()
+}
+
+var _ ChainClient = (*InsightClient)(nil)
@@ -0,0 +1,117 @@
+package client
+
+// Block is a single block header as returned by Insight's `blocks` endpoint
+// and Blockbook's equivalent block index call.
+type Block struct {
+	Hash          string `json:"hash"`
+	Height        int32  `json:"height"`
+	Confirmations int32  `json:"confirmations"`
+	Size          int32  `json:"size"`
+	Time          int64  `json:"time"`
+	Version       int32  `json:"version"`
+	MerkleRoot    string `json:"merkleroot"`
+	Bits          string `json:"bits"`
+	Difficulty    string `json:"difficulty"`
+	Parent        string `json:"previousblockhash"`
+}
+
+// BlockSummaryList is the response shape of Insight's `blocks?limit=n` endpoint.
+type BlockSummaryList struct {
+	Blocks []Block `json:"blocks"`
+}
+
+// Input is a single transaction input as returned by the Insight/Blockbook
+// transaction formatters. ValueIface holds the raw decoded value, which the
+// API may send as either a float64 or a string; Value is always populated
+// with the float64 equivalent after decoding.
+type Input struct {
+	Txid       string      `json:"txid"`
+	Vout       uint32      `json:"vout"`
+	Sequence   int64       `json:"sequence"`
+	N          int         `json:"n"`
+	Addr       string      `json:"addr"`
+	Satoshis   int64       `json:"valueSat"`
+	ValueIface interface{} `json:"value"`
+	Value      float64     `json:"-"`
+	ScriptSig  struct {
+		Hex string `json:"hex"`
+		Asm string `json:"asm"`
+	} `json:"scriptSig"`
+}
+
+// Output is a single transaction output.
+type Output struct {
+	N            int         `json:"n"`
+	Satoshis     int64       `json:"valueSat"`
+	ValueIface   interface{} `json:"value"`
+	Value        float64     `json:"-"`
+	ScriptPubKey struct {
+		Hex       string   `json:"hex"`
+		Asm       string   `json:"asm"`
+		Addresses []string `json:"addresses"`
+		Type      string   `json:"type"`
+	} `json:"scriptPubKey"`
+	SpentTxId string `json:"spentTxId"`
+}
+
+// Transaction is a decoded transaction as returned by GetTransaction and
+// GetTransactions.
+type Transaction struct {
+	Txid          string   `json:"txid"`
+	Version       int32    `json:"version"`
+	Locktime      int32    `json:"locktime"`
+	Inputs        []Input  `json:"vin"`
+	Outputs       []Output `json:"vout"`
+	BlockHash     string   `json:"blockhash"`
+	BlockHeight   int32    `json:"blockheight"`
+	Confirmations int32    `json:"confirmations"`
+	Time          int64    `json:"time"`
+	BlockTime     int64    `json:"blocktime"`
+	Size          int32    `json:"size"`
+
+	// RateAtConfirmation is the fiat exchange rate at the transaction's
+	// confirmation time. GetTransaction/GetTransactions/GetAddressHistory
+	// populate it once SetFiatRateProvider has configured both a provider
+	// and a currency; it is left nil otherwise so callers can tell
+	// "no data" apart from a zero rate.
+	RateAtConfirmation *float64 `json:"-"`
+}
+
+// TransactionList is the paginated response from the addrs/txs endpoint.
+type TransactionList struct {
+	TotalItems int           `json:"totalItems"`
+	From       int           `json:"from"`
+	To         int           `json:"to"`
+	Items      []Transaction `json:"items"`
+}
+
+// Utxo is a single unspent output as returned by the addrs/utxo endpoint.
+type Utxo struct {
+	Address       string      `json:"address"`
+	Txid          string      `json:"txid"`
+	Vout          uint32      `json:"vout"`
+	ScriptPubKey  string      `json:"scriptPubKey"`
+	Satoshis      int64       `json:"satoshis"`
+	AmountIface   interface{} `json:"amount"`
+	Amount        float64     `json:"-"`
+	Confirmations int64       `json:"confirmations"`
+	BlockHeight   int64       `json:"height"`
+	// Time is the unix time the utxo's transaction confirmed (or was first
+	// seen in the mempool), as returned in the "ts" field Insight/Blockbook
+	// include on addrs/utxo and getUtxo responses.
+	Time int64 `json:"ts"`
+
+	// RateAtConfirmation is the fiat exchange rate at Time. GetUtxos
+	// populates it once SetFiatRateProvider has configured both a provider
+	// and a currency; it is left nil otherwise so callers can tell
+	// "no data" apart from a zero rate.
+	RateAtConfirmation *float64 `json:"-"`
+}
+
+// FiatRate is a single currency/rate sample, either the current rate or a
+// historical one looked up for a specific timestamp.
+type FiatRate struct {
+	Currency  string  `json:"currency"`
+	Rate      float64 `json:"rate"`
+	Timestamp int64   `json:"timestamp"`
+}
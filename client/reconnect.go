@@ -0,0 +1,127 @@
+package client
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/OpenBazaar/golang-socketio"
+)
+
+// ConnectionState describes whether InsightClient currently has a live
+// socket.io connection.
+type ConnectionState int
+
+const (
+	OFFLINE ConnectionState = iota
+	ONLINE
+)
+
+const (
+	// defaultNotifyBufferSize sizes blockNotifyChan and txNotifyChan so a
+	// slow consumer doesn't wedge the socket read loop.
+	defaultNotifyBufferSize = 32
+
+	minBackoff = time.Second
+	maxBackoff = time.Minute
+)
+
+// backoff returns an exponential delay with jitter for the given retry
+// attempt (0-indexed), capped at maxBackoff.
+func backoff(attempt int) time.Duration {
+	d := minBackoff * (1 << uint(attempt))
+	if d > maxBackoff || d <= 0 {
+		d = maxBackoff
+	}
+	jitter := time.Duration(rand.Int63n(int64(d) / 2))
+	return d/2 + jitter
+}
+
+// runSupervisor watches for socket disconnects and reconnects with
+// exponential backoff, replaying every subscription that was active before
+// the drop. It runs for the lifetime of the InsightClient.
+func (i *InsightClient) runSupervisor() {
+	for {
+		select {
+		case <-i.closeChan:
+			return
+		case <-i.disconnectChan:
+		}
+
+		i.pushConnState(OFFLINE)
+		var attempt int
+		for {
+			select {
+			case <-i.closeChan:
+				return
+			case <-time.After(backoff(attempt)):
+			}
+
+			socketClient, err := dialInsightSocket(i.apiUrl, i.proxyDialer)
+			if err != nil {
+				log.Errorf("Error reconnecting to insight socket: %s", err.Error())
+				attempt++
+				continue
+			}
+			i.setSocketClient(socketClient)
+			i.watchDisconnect()
+			i.setupListeners()
+			i.resubscribe()
+			i.pushConnState(ONLINE)
+			break
+		}
+	}
+}
+
+// watchDisconnect registers a handler that signals disconnectChan exactly
+// once the next time the current socket drops.
+func (i *InsightClient) watchDisconnect() {
+	i.getSocketClient().On(gosocketio.OnDisconnection, func(h *gosocketio.Channel, args interface{}) {
+		select {
+		case i.disconnectChan <- struct{}{}:
+		default:
+		}
+	})
+}
+
+// resubscribe replays every ListenAddress/ListenAddresses subscription
+// against the current socket in a single emit. Called after a reconnect, once
+// setupListeners has re-emitted the bitcoind/hashblock subscription, so
+// subscriptions survive a dropped connection.
+func (i *InsightClient) resubscribe() {
+	i.subscribedAddrsMtx.Lock()
+	addrs := make([]string, 0, len(i.subscribedAddrs))
+	for addr := range i.subscribedAddrs {
+		addrs = append(addrs, addr)
+	}
+	i.subscribedAddrsMtx.Unlock()
+
+	if len(addrs) > 0 {
+		args := []interface{}{"bitcoind/addresstxid", addrs}
+		i.getSocketClient().Emit("subscribe", args)
+	}
+}
+
+// ConnectionState reports socket connectivity so wallet code can pause
+// rescans while the backend is offline rather than silently missing
+// notifications.
+func (i *InsightClient) ConnectionState() <-chan ConnectionState {
+	return i.connStateChan
+}
+
+// pushConnState delivers s without blocking, dropping the previously queued
+// state if the caller hasn't drained it yet. connStateChan only needs to
+// reflect the latest state, and runSupervisor must never stall here or it
+// stops watching disconnectChan entirely.
+func (i *InsightClient) pushConnState(s ConnectionState) {
+	for {
+		select {
+		case i.connStateChan <- s:
+			return
+		default:
+		}
+		select {
+		case <-i.connStateChan:
+		default:
+		}
+	}
+}